@@ -0,0 +1,94 @@
+package sql2csv
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// boundedPipe is an in-memory io.Reader/io.Writer pair like io.Pipe, except
+// writes only block once max bytes are buffered and unread, instead of
+// synchronizing every single Write with a Read. This lets a producer run
+// ahead of a slower consumer by up to max bytes instead of lockstepping with
+// it one Write call at a time.
+type boundedPipe struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  bytes.Buffer
+	max  int
+
+	closed bool
+	cerr   error
+}
+
+func newBoundedPipe(max int) *boundedPipe {
+	p := &boundedPipe{max: max}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Write implements io.Writer, blocking while the buffer is full rather than
+// until a Read drains it entirely.
+func (p *boundedPipe) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	written := 0
+	for len(b) > 0 {
+		for p.buf.Len() >= p.max && !p.closed {
+			p.cond.Wait()
+		}
+		if p.closed {
+			if p.cerr != nil {
+				return written, p.cerr
+			}
+			return written, io.ErrClosedPipe
+		}
+
+		room := p.max - p.buf.Len()
+		n := len(b)
+		if n > room {
+			n = room
+		}
+		p.buf.Write(b[:n])
+		b = b[n:]
+		written += n
+		p.cond.Broadcast()
+	}
+	return written, nil
+}
+
+// Read implements io.Reader, returning cerr (or io.EOF if cerr is nil) once
+// the buffer is drained and CloseWithError has been called.
+func (p *boundedPipe) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.buf.Len() == 0 && !p.closed {
+		p.cond.Wait()
+	}
+	if p.buf.Len() == 0 {
+		if p.cerr != nil {
+			return 0, p.cerr
+		}
+		return 0, io.EOF
+	}
+
+	n, _ := p.buf.Read(b)
+	p.cond.Broadcast()
+	return n, nil
+}
+
+// CloseWithError marks the pipe closed, unblocking any Write and, once the
+// buffer is drained, causing Read to return err (or io.EOF if err is nil).
+// Only the first call has an effect, mirroring io.PipeWriter.CloseWithError.
+func (p *boundedPipe) CloseWithError(err error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		p.cerr = err
+	}
+	p.cond.Broadcast()
+	return nil
+}
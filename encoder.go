@@ -0,0 +1,189 @@
+package sql2csv
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// Encoder is the interface a row-serialization format implements in order to
+// be used by Converter.Write. Built-in encoders are CSVEncoder (the default),
+// TSVEncoder, JSONLinesEncoder and SQLInsertEncoder.
+//
+// Open binds the encoder to the writer for this conversion; WriteHeader and
+// WriteRow are then called once per header row / data row, and Flush is
+// called once after the last row has been read (also when rows.Err reports
+// an error). When Write aborts early on a header, scan or row error, it
+// returns without calling Flush.
+type Encoder interface {
+	Open(w io.Writer) error
+	WriteHeader(headers []string) error
+	WriteRow(row []string, rawValues []interface{}, columnTypes []*sql.ColumnType) error
+	Flush() error
+}
+
+// CSVEncoder is the default Encoder, and reproduces sql2csv's original
+// behaviour by wrapping encoding/csv.Writer for as long as that's capable of
+// expressing the requested options. Comma defaults to a comma and can be
+// overridden before the conversion starts; the other fields below cover
+// things encoding/csv can't do (a multi-byte separator, suppressing quoting
+// entirely, backslash-style escaping or a CRLF line terminator), in which
+// case CSVEncoder falls back to writing fields itself. NullString alone does
+// not cause a fallback; it is applied on either path.
+type CSVEncoder struct {
+	Comma rune // single-byte field delimiter, default comma; ignored if Separator is set
+
+	Separator       string // field delimiter, may be more than one byte; overrides Comma
+	Quote           rune   // quote character, default '"'; only consulted unless DisableQuoting is set
+	DisableQuoting  bool   // never quote fields, even ones containing the separator or a newline
+	NullString      string // token written in place of a SQL NULL, e.g. `\N`, to distinguish it from an empty string
+	LineTerminator  string // row terminator, default "\n"; set to "\r\n" for CRLF
+	EscapeBackslash bool   // backslash-escape embedded quotes/newlines instead of doubling the quote character
+
+	w      io.Writer
+	stdlib *csv.Writer
+	custom bool
+}
+
+// Open implements Encoder.
+func (e *CSVEncoder) Open(w io.Writer) error {
+	e.w = w
+	e.custom = e.needsCustomWriter()
+	if e.custom {
+		return nil
+	}
+
+	csvWriter := csv.NewWriter(w)
+	switch {
+	case len(e.Separator) == 1:
+		csvWriter.Comma = rune(e.Separator[0])
+	case e.Comma != 0:
+		csvWriter.Comma = e.Comma
+	}
+	e.stdlib = csvWriter
+	return nil
+}
+
+// needsCustomWriter reports whether any option is set that encoding/csv
+// can't express, so CSVEncoder must bypass it and write fields itself.
+func (e *CSVEncoder) needsCustomWriter() bool {
+	return len(e.Separator) > 1 ||
+		(e.Quote != 0 && e.Quote != '"') ||
+		e.DisableQuoting ||
+		e.EscapeBackslash ||
+		(e.LineTerminator != "" && e.LineTerminator != "\n")
+}
+
+// WriteHeader implements Encoder.
+func (e *CSVEncoder) WriteHeader(headers []string) error {
+	if e.custom {
+		return e.writeCustomRow(headers)
+	}
+	return e.stdlib.Write(headers)
+}
+
+// WriteRow implements Encoder.
+func (e *CSVEncoder) WriteRow(row []string, rawValues []interface{}, _ []*sql.ColumnType) error {
+	row = e.applyNullString(row, rawValues)
+	if e.custom {
+		return e.writeCustomRow(row)
+	}
+	return e.stdlib.Write(row)
+}
+
+// Flush implements Encoder.
+func (e *CSVEncoder) Flush() error {
+	if e.custom {
+		return nil
+	}
+	e.stdlib.Flush()
+	return e.stdlib.Error()
+}
+
+// applyNullString substitutes NullString for fields whose raw scanned value
+// was SQL NULL, leaving an ordinary empty string as-is.
+func (e *CSVEncoder) applyNullString(row []string, rawValues []interface{}) []string {
+	if e.NullString == "" {
+		return row
+	}
+
+	out := make([]string, len(row))
+	copy(out, row)
+	for i := range rawValues {
+		if i < len(out) && rawValues[i] == nil {
+			out[i] = e.NullString
+		}
+	}
+	return out
+}
+
+func (e *CSVEncoder) separator() string {
+	if e.Separator != "" {
+		return e.Separator
+	}
+	if e.Comma != 0 {
+		return string(e.Comma)
+	}
+	return ","
+}
+
+func (e *CSVEncoder) quote() rune {
+	if e.Quote != 0 {
+		return e.Quote
+	}
+	return '"'
+}
+
+func (e *CSVEncoder) writeCustomRow(row []string) error {
+	sep := e.separator()
+	terminator := e.LineTerminator
+	if terminator == "" {
+		terminator = "\n"
+	}
+
+	fields := make([]string, len(row))
+	for i, field := range row {
+		fields[i] = e.encodeField(field, sep)
+	}
+
+	_, err := io.WriteString(e.w, strings.Join(fields, sep)+terminator)
+	return err
+}
+
+func (e *CSVEncoder) encodeField(field, sep string) string {
+	if e.DisableQuoting {
+		if e.EscapeBackslash {
+			field = backslashEscaper.Replace(field)
+		}
+		return field
+	}
+
+	quote := e.quote()
+	if !strings.Contains(field, sep) && !strings.ContainsRune(field, quote) && !strings.ContainsAny(field, "\n\r") {
+		return field
+	}
+
+	var escaped string
+	if e.EscapeBackslash {
+		escaped = strings.NewReplacer(`\`, `\\`, string(quote), `\`+string(quote), "\n", `\n`, "\r", `\r`).Replace(field)
+	} else {
+		escaped = strings.ReplaceAll(field, string(quote), string(quote)+string(quote))
+	}
+	return string(quote) + escaped + string(quote)
+}
+
+var backslashEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+// TSVEncoder is a CSVEncoder whose field separator is a tab instead of a
+// comma. It otherwise behaves identically, including encoding/csv's usual
+// quoting rules.
+type TSVEncoder struct {
+	CSVEncoder
+}
+
+// Open implements Encoder.
+func (e *TSVEncoder) Open(w io.Writer) error {
+	e.Comma = '\t'
+	return e.CSVEncoder.Open(w)
+}
@@ -0,0 +1,87 @@
+package sql2csv
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestTSVEncoder(t *testing.T) {
+	converter := getConverter(t)
+	converter.WithEncoder(&TSVEncoder{})
+
+	expected := "name\tage\tbdate\nAlice\t1\t1973-11-29 21:33:09 +0000 UTC\n"
+	actual := converter.String()
+
+	assertCsvMatch(t, expected, actual)
+}
+
+func TestJSONLinesEncoder(t *testing.T) {
+	converter := getConverter(t)
+	converter.WriteHeaders = true
+	converter.WithEncoder(&JSONLinesEncoder{})
+
+	actual := converter.String()
+
+	if !strings.Contains(actual, `"name":"Alice"`) {
+		t.Errorf("expected JSON Lines output to contain the row as a typed object, got: %v", actual)
+	}
+	if !strings.Contains(actual, `"age":1`) {
+		t.Errorf("expected age to be encoded as a JSON number, not a string, got: %v", actual)
+	}
+}
+
+func TestCSVEncoderNullString(t *testing.T) {
+	converter := NewConverter(getTestRowsByQuery(t, "SELECT|people|name,nickname,age|"))
+	converter.WithEncoder(&CSVEncoder{NullString: `\N`})
+
+	expected := "name,nickname,age\nAlice,\\N,1\n"
+	actual := converter.String()
+
+	assertCsvMatch(t, expected, actual)
+}
+
+func TestCSVEncoderMultiByteSeparatorAndCRLF(t *testing.T) {
+	converter := getConverter(t)
+	converter.WithEncoder(&CSVEncoder{Separator: " | ", LineTerminator: "\r\n"})
+
+	expected := "name | age | bdate\r\nAlice | 1 | 1973-11-29 21:33:09 +0000 UTC\r\n"
+	actual := converter.String()
+
+	assertCsvMatch(t, expected, actual)
+}
+
+func TestCSVEncoderSingleByteSeparator(t *testing.T) {
+	converter := getConverter(t)
+	converter.WithEncoder(&CSVEncoder{Separator: "|"})
+
+	expected := "name|age|bdate\nAlice|1|1973-11-29 21:33:09 +0000 UTC\n"
+	actual := converter.String()
+
+	assertCsvMatch(t, expected, actual)
+}
+
+func TestCSVEncoderCustomQuote(t *testing.T) {
+	converter := getConverter(t)
+	converter.WithEncoder(&CSVEncoder{Quote: '\''})
+	converter.SetRowPostProcessor(func(row []string, _ []*sql.ColumnType) (bool, []string) {
+		row[0] = "Alice, Jr." // force quoting by giving the field an embedded separator
+		return true, row
+	})
+
+	expected := "name,age,bdate\n'Alice, Jr.',1,1973-11-29 21:33:09 +0000 UTC\n"
+	actual := converter.String()
+
+	assertCsvMatch(t, expected, actual)
+}
+
+func TestSQLInsertEncoder(t *testing.T) {
+	converter := getConverter(t)
+	converter.WriteHeaders = true
+	converter.WithEncoder(&SQLInsertEncoder{TableName: "people", BatchSize: 10})
+
+	expected := "INSERT INTO people (name, age, bdate) VALUES ('Alice', '1', '1973-11-29 21:33:09 +0000 UTC');\n"
+	actual := converter.String()
+
+	assertCsvMatch(t, expected, actual)
+}
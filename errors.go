@@ -0,0 +1,85 @@
+package sql2csv
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ConversionError carries row/column context around an error encountered
+// while converting one row, so a Converter.OnError hook (or a caller
+// inspecting the error Write returned) can tell which row and column failed
+// instead of getting a bare error from rows.Scan, uuid.FromBytes or the
+// Encoder.
+type ConversionError struct {
+	RowIndex   int             // zero-based index of the data row, not counting the header
+	ColumnName string          // empty when the error isn't attributable to one column, e.g. a Scan failure
+	ColumnType *sql.ColumnType // nil under the same circumstances as ColumnName
+	RawValue   interface{}     // the raw value that failed to convert, if known
+	Err        error           // the underlying error
+}
+
+func (e *ConversionError) Error() string {
+	if e.ColumnName != "" {
+		return fmt.Sprintf("sql2csv: row %d, column %q: %v", e.RowIndex, e.ColumnName, e.Err)
+	}
+	return fmt.Sprintf("sql2csv: row %d: %v", e.RowIndex, e.Err)
+}
+
+// Unwrap lets errors.Is/errors.As see through to the underlying error.
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorActionKind is the decision an ErrorHandler makes about a ConversionError.
+type ErrorActionKind int
+
+const (
+	// AbortConversion stops Write and returns the ConversionError. This is
+	// the default when Converter.OnError is nil.
+	AbortConversion ErrorActionKind = iota
+	// SkipRow drops the offending row entirely and continues with the next one.
+	SkipRow
+	// UseFallback substitutes ErrorAction.Fallback for the failing cell (or,
+	// for a row-level error such as a Scan failure, drops the row, since
+	// there's no single cell to substitute a value into).
+	UseFallback
+)
+
+// ErrorAction is what an ErrorHandler returns to tell Write how to proceed
+// past a ConversionError.
+type ErrorAction struct {
+	Kind     ErrorActionKind
+	Fallback string // consulted only when Kind == UseFallback
+}
+
+// ErrorHandler decides what Converter.Write should do about a ConversionError.
+type ErrorHandler func(ConversionError) ErrorAction
+
+// Abort is an ErrorHandler that always aborts the conversion. It's the same
+// as leaving Converter.OnError unset; it's provided so "abort" can be named
+// explicitly alongside Skip and UseFallbackValue.
+func Abort(ConversionError) ErrorAction {
+	return ErrorAction{Kind: AbortConversion}
+}
+
+// Skip is an ErrorHandler that drops every offending row and keeps
+// converting, so a single bad row doesn't kill a multi-hour export.
+func Skip(ConversionError) ErrorAction {
+	return ErrorAction{Kind: SkipRow}
+}
+
+// UseFallbackValue returns an ErrorHandler that substitutes fallback for
+// every failing cell and keeps converting.
+func UseFallbackValue(fallback string) ErrorHandler {
+	return func(ConversionError) ErrorAction {
+		return ErrorAction{Kind: UseFallback, Fallback: fallback}
+	}
+}
+
+// handleConversionError runs c.OnError (or the default Abort) for convErr.
+func (c Converter) handleConversionError(convErr *ConversionError) ErrorAction {
+	if c.OnError == nil {
+		return ErrorAction{Kind: AbortConversion}
+	}
+	return c.OnError(*convErr)
+}
@@ -0,0 +1,68 @@
+package sql2csv
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingEncoder wraps a CSVEncoder and fails the row at failOnRow (0-based),
+// letting tests exercise Converter.OnError without depending on the test
+// driver producing a real Scan or UUID-parsing failure.
+type failingEncoder struct {
+	CSVEncoder
+	failOnRow int
+	seen      int
+}
+
+func (e *failingEncoder) WriteRow(row []string, rawValues []interface{}, columnTypes []*sql.ColumnType) error {
+	defer func() { e.seen++ }()
+	if e.seen == e.failOnRow {
+		return errors.New("boom")
+	}
+	return e.CSVEncoder.WriteRow(row, rawValues, columnTypes)
+}
+
+func twoPersonRows(t *testing.T) *sql.Rows {
+	db := setupDatabase(t)
+	exec(t, db, "INSERT|people|name=Bob,age=?,bdate=?,nickname=?", 2, time.Unix(123456789, 0), nil)
+
+	rows, err := db.Query("SELECT|people|name,age,bdate|")
+	if err != nil {
+		t.Fatalf("error querying: %v", err)
+	}
+	return rows
+}
+
+func TestWriteAbortsByDefaultOnEncoderError(t *testing.T) {
+	converter := NewConverter(twoPersonRows(t))
+	converter.WithEncoder(&failingEncoder{failOnRow: 0})
+
+	_, err := converter.WriteString()
+	if err == nil {
+		t.Fatal("expected an error from the failing encoder")
+	}
+
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("expected a *ConversionError, got %T: %v", err, err)
+	}
+	if convErr.RowIndex != 0 {
+		t.Errorf("expected RowIndex 0, got %d", convErr.RowIndex)
+	}
+}
+
+func TestWriteSkipsRowOnEncoderErrorWithSkip(t *testing.T) {
+	converter := NewConverter(twoPersonRows(t))
+	converter.OnError = Skip
+	converter.WithEncoder(&failingEncoder{failOnRow: 0})
+
+	actual, err := converter.WriteString()
+	if err != nil {
+		t.Fatalf("expected Skip to suppress the error, got: %v", err)
+	}
+
+	expected := "name,age,bdate\nBob,2,1973-11-29 21:33:09 +0000 UTC\n"
+	assertCsvMatch(t, expected, actual)
+}
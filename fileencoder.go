@@ -0,0 +1,132 @@
+package sql2csv
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RotationOptions bounds how large a single output file is allowed to grow
+// before RotatingFileEncoder starts a new one.
+type RotationOptions struct {
+	MaxRowsPerFile  int64 // rotate after this many rows; <= 0 disables row-based rotation
+	MaxBytesPerFile int64 // rotate after roughly this many bytes; <= 0 disables byte-based rotation
+}
+
+// RotatingFileEncoder wraps another Encoder (built by NewEncoder) and splits
+// its output across size-bounded files named fmt.Sprintf("%s.part%04d%s",
+// Prefix, partIndex, Ext), writing the header again at the top of every new
+// file so each part is independently usable. Every file it creates is
+// appended to Manifest, in the order they were written.
+type RotatingFileEncoder struct {
+	Prefix     string
+	Ext        string
+	NewEncoder func() Encoder // builds the encoder used for each part file, e.g. func() Encoder { return &CSVEncoder{} }
+	RotationOptions
+
+	Manifest []string
+
+	headers   []string
+	rows      int64
+	written   *countingWriter
+	file      *os.File
+	partIndex int
+	enc       Encoder
+}
+
+// Open implements Encoder. The io.Writer passed in is ignored: each rotated
+// part is its own file, opened by RotatingFileEncoder itself.
+func (e *RotatingFileEncoder) Open(io.Writer) error {
+	return e.rotate()
+}
+
+// WriteHeader implements Encoder.
+func (e *RotatingFileEncoder) WriteHeader(headers []string) error {
+	e.headers = headers
+	return e.enc.WriteHeader(headers)
+}
+
+// WriteRow implements Encoder.
+func (e *RotatingFileEncoder) WriteRow(row []string, rawValues []interface{}, columnTypes []*sql.ColumnType) error {
+	if e.shouldRotate() {
+		if err := e.rotate(); err != nil {
+			return err
+		}
+	}
+	if err := e.enc.WriteRow(row, rawValues, columnTypes); err != nil {
+		return err
+	}
+	e.rows++
+	return nil
+}
+
+// Flush implements Encoder.
+func (e *RotatingFileEncoder) Flush() error {
+	if e.enc == nil {
+		return nil
+	}
+	if err := e.enc.Flush(); err != nil {
+		return err
+	}
+	return e.file.Close()
+}
+
+func (e *RotatingFileEncoder) shouldRotate() bool {
+	if e.rows == 0 {
+		return false // never rotate away an empty, just-opened file
+	}
+	if e.MaxRowsPerFile > 0 && e.rows >= e.MaxRowsPerFile {
+		return true
+	}
+	if e.MaxBytesPerFile > 0 && e.written.n >= e.MaxBytesPerFile {
+		return true
+	}
+	return false
+}
+
+func (e *RotatingFileEncoder) rotate() error {
+	if e.file != nil {
+		if err := e.enc.Flush(); err != nil {
+			return err
+		}
+		if err := e.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	name := fmt.Sprintf("%s.part%04d%s", e.Prefix, e.partIndex, e.Ext)
+	e.partIndex++
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	e.file = f
+	e.Manifest = append(e.Manifest, name)
+	e.written = &countingWriter{w: f}
+	e.rows = 0
+
+	e.enc = e.NewEncoder()
+	if err = e.enc.Open(e.written); err != nil {
+		return err
+	}
+	if len(e.headers) > 0 {
+		return e.enc.WriteHeader(e.headers)
+	}
+	return nil
+}
+
+// countingWriter tracks how many bytes have been written through it, so
+// RotatingFileEncoder can rotate on MaxBytesPerFile without every wrapped
+// Encoder needing to expose its own byte count.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,50 @@
+package sql2csv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFileEncoderRotatesOnMaxRows(t *testing.T) {
+	dir := t.TempDir()
+	prefix := filepath.Join(dir, "out")
+
+	enc := &RotatingFileEncoder{
+		Prefix:          prefix,
+		Ext:             ".csv",
+		NewEncoder:      func() Encoder { return &CSVEncoder{} },
+		RotationOptions: RotationOptions{MaxRowsPerFile: 2},
+	}
+
+	if err := enc.Open(nil); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := enc.WriteHeader([]string{"name", "age"}); err != nil {
+		t.Fatalf("WriteHeader: %v", err)
+	}
+	for _, row := range [][]string{{"Alice", "1"}, {"Bob", "2"}, {"Carol", "3"}} {
+		if err := enc.WriteRow(row, nil, nil); err != nil {
+			t.Fatalf("WriteRow(%v): %v", row, err)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if len(enc.Manifest) != 2 {
+		t.Fatalf("expected 2 part files, got %d: %v", len(enc.Manifest), enc.Manifest)
+	}
+
+	part0, err := os.ReadFile(enc.Manifest[0])
+	if err != nil {
+		t.Fatalf("reading %v: %v", enc.Manifest[0], err)
+	}
+	assertCsvMatch(t, "name,age\nAlice,1\nBob,2\n", string(part0))
+
+	part1, err := os.ReadFile(enc.Manifest[1])
+	if err != nil {
+		t.Fatalf("reading %v: %v", enc.Manifest[1], err)
+	}
+	assertCsvMatch(t, "name,age\nCarol,3\n", string(part1))
+}
@@ -0,0 +1,62 @@
+package sql2csv
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+)
+
+// JSONLinesEncoder writes one JSON object per row (https://jsonlines.org),
+// keyed by column name, using the raw typed values scanned from sql.Rows
+// rather than their string representation. This preserves numeric, boolean
+// and null values instead of flattening everything to strings the way
+// CSVEncoder has to.
+type JSONLinesEncoder struct {
+	w       io.Writer
+	headers []string
+}
+
+// Open implements Encoder.
+func (e *JSONLinesEncoder) Open(w io.Writer) error {
+	e.w = w
+	return nil
+}
+
+// WriteHeader implements Encoder. JSON Lines has no header row of its own;
+// the headers are remembered as the object keys for WriteRow.
+func (e *JSONLinesEncoder) WriteHeader(headers []string) error {
+	e.headers = headers
+	return nil
+}
+
+// WriteRow implements Encoder.
+func (e *JSONLinesEncoder) WriteRow(row []string, rawValues []interface{}, columnTypes []*sql.ColumnType) error {
+	obj := make(map[string]interface{}, len(columnTypes))
+	for i, column := range columnTypes {
+		name := column.Name()
+		if i < len(e.headers) {
+			name = e.headers[i]
+		}
+		if i < len(rawValues) {
+			obj[name] = rawValues[i]
+		} else if i < len(row) {
+			obj[name] = row[i]
+		}
+	}
+
+	line, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if _, err = e.w.Write(line); err != nil {
+		return err
+	}
+	_, err = e.w.Write([]byte("\n"))
+	return err
+}
+
+// Flush implements Encoder. JSONLinesEncoder writes eagerly, so there's
+// nothing to flush.
+func (e *JSONLinesEncoder) Flush() error {
+	return nil
+}
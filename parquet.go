@@ -0,0 +1,38 @@
+package sql2csv
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+)
+
+// ErrParquetNotImplemented is returned by ParquetEncoder until this package
+// takes a dependency on a Parquet writer library.
+var ErrParquetNotImplemented = errors.New("sql2csv: Parquet output requires a parquet writer library and isn't wired up yet")
+
+// ParquetEncoder is a placeholder Encoder satisfying the same interface as
+// CSVEncoder, TSVEncoder, JSONLinesEncoder and SQLInsertEncoder, so that
+// callers can already code against `Converter.WithEncoder(&ParquetEncoder{})`
+// ahead of an actual implementation landing (e.g. on top of
+// github.com/xitongsys/parquet-go or github.com/apache/arrow/go/parquet).
+type ParquetEncoder struct{}
+
+// Open implements Encoder.
+func (e *ParquetEncoder) Open(io.Writer) error {
+	return ErrParquetNotImplemented
+}
+
+// WriteHeader implements Encoder.
+func (e *ParquetEncoder) WriteHeader([]string) error {
+	return ErrParquetNotImplemented
+}
+
+// WriteRow implements Encoder.
+func (e *ParquetEncoder) WriteRow([]string, []interface{}, []*sql.ColumnType) error {
+	return ErrParquetNotImplemented
+}
+
+// Flush implements Encoder.
+func (e *ParquetEncoder) Flush() error {
+	return ErrParquetNotImplemented
+}
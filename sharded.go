@@ -0,0 +1,188 @@
+package sql2csv
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ShardRange bounds one shard's WHERE clause. It's substituted into
+// ConverterPool.QueryTemplate's two `?` placeholders, e.g.
+// "SELECT * FROM t WHERE id BETWEEN ? AND ?".
+type ShardRange struct {
+	Low  interface{}
+	High interface{}
+}
+
+// ConverterPool runs QueryTemplate once per entry in Shards, each against
+// its own *sql.Rows and Converter, so a large table can be dumped at close
+// to disk/network speed instead of through a single rows.Next() loop.
+type ConverterPool struct {
+	DB            *sql.DB
+	QueryTemplate string       // query with two `?` placeholders for ShardRange.Low/High
+	Shards        []ShardRange
+	Concurrency   int // max shards queried at once; <= 0 means len(Shards)
+
+	// NewConverter builds the Converter used for each shard's rows, letting
+	// callers set Delimiter, Encoder, TimeFormat etc. It defaults to
+	// sql2csv.NewConverter.
+	NewConverter func(rows *sql.Rows) *Converter
+}
+
+// shardWriteBufferSize bounds how far a shard's conversion may run ahead of
+// the merge goroutine before Write blocks it, in bytes. It's large enough
+// that a shard's conversion keeps overlapping with the shards ahead of it
+// draining, without holding more than a few shards' worth of output in
+// memory at once regardless of table size.
+const shardWriteBufferSize = 256 * 1024
+
+// Write runs every shard concurrently (bounded by Concurrency) and streams
+// their CSV rows into w in shard order, with the header written exactly
+// once, taken from the first shard. Each shard is piped into w through a
+// shardWriteBufferSize-bounded buffer as its turn comes up, so a later shard
+// can keep querying and converting concurrently with earlier ones draining,
+// up to that many bytes ahead, instead of blocking on its very first row
+// until every row ahead of it has been written.
+func (p *ConverterPool) Write(ctx context.Context, w io.Writer) error {
+	pipes := make([]*boundedPipe, len(p.Shards))
+	for i := range p.Shards {
+		pipes[i] = newBoundedPipe(shardWriteBufferSize)
+	}
+
+	mergeErrCh := make(chan error, 1)
+	go func() {
+		for i, pipe := range pipes {
+			if _, err := io.Copy(w, pipe); err != nil {
+				// Unblock every shard still writing so its goroutine can
+				// exit instead of leaking on a pipe nobody reads from.
+				for _, rest := range pipes[i:] {
+					_, _ = io.Copy(io.Discard, rest)
+				}
+				mergeErrCh <- err
+				return
+			}
+		}
+		mergeErrCh <- nil
+	}()
+
+	runErr := p.run(ctx, func(i int, rows *sql.Rows) error {
+		converter := p.newConverter(rows)
+		converter.WriteHeaders = i == 0
+		writeErr := converter.Write(pipes[i])
+		_ = pipes[i].CloseWithError(writeErr)
+		return writeErr
+	}, func(i int, queryErr error) {
+		_ = pipes[i].CloseWithError(queryErr)
+	})
+
+	mergeErr := <-mergeErrCh
+	if runErr != nil {
+		return runErr
+	}
+	return mergeErr
+}
+
+// WriteFiles runs every shard concurrently and writes each one to its own
+// set of part files named fmt.Sprintf("%s.shard%04d.part%04d.csv", prefix,
+// shardIndex, partIndex), rotating to a new part file per shard according
+// to opts. It returns every part file it created, in shard then part order,
+// which callers can use as (or write out as) a manifest. Each part file is
+// written by a CSVEncoder with default settings: an Encoder set by
+// NewConverter is replaced, so it (and Delimiter) has no effect here.
+func (p *ConverterPool) WriteFiles(ctx context.Context, prefix string, opts RotationOptions) ([]string, error) {
+	manifests := make([][]string, len(p.Shards))
+
+	if err := p.run(ctx, func(i int, rows *sql.Rows) error {
+		converter := p.newConverter(rows)
+		rotator := &RotatingFileEncoder{
+			Prefix:          fmt.Sprintf("%s.shard%04d", prefix, i),
+			Ext:             ".csv",
+			NewEncoder:      func() Encoder { return &CSVEncoder{} },
+			RotationOptions: opts,
+		}
+		converter.WithEncoder(rotator)
+
+		buf := bytes.Buffer{} // converter.Write needs an io.Writer; RotatingFileEncoder ignores it
+		if err := converter.Write(&buf); err != nil {
+			return err
+		}
+		manifests[i] = rotator.Manifest
+		return nil
+	}, nil); err != nil {
+		return nil, err
+	}
+
+	var manifest []string
+	for _, m := range manifests {
+		manifest = append(manifest, m...)
+	}
+	return manifest, nil
+}
+
+func (p *ConverterPool) newConverter(rows *sql.Rows) *Converter {
+	if p.NewConverter != nil {
+		return p.NewConverter(rows)
+	}
+	return NewConverter(rows)
+}
+
+// run executes fn(i, rows) for every shard, at most Concurrency at a time,
+// and returns the first error encountered (after every in-flight shard has
+// finished, so no shard is still writing once run returns; output a shard
+// wrote before the error is left in place). onQueryError, if
+// non-nil, is called instead of fn when shard i's query itself fails, so a
+// caller that handed fn something that needs closing either way (e.g. a
+// pipe) still gets the chance to close it.
+func (p *ConverterPool) run(ctx context.Context, fn func(i int, rows *sql.Rows) error, onQueryError func(i int, err error)) error {
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = len(p.Shards)
+	}
+	if concurrency <= 0 {
+		return nil
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	setErr := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+
+	for i, shard := range p.Shards {
+		i, shard := i, shard
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			rows, err := p.DB.QueryContext(ctx, p.QueryTemplate, shard.Low, shard.High)
+			if err != nil {
+				err = fmt.Errorf("sql2csv: querying shard %d: %w", i, err)
+				setErr(err)
+				if onQueryError != nil {
+					onQueryError(i, err)
+				}
+				return
+			}
+			defer rows.Close()
+
+			if err = fn(i, rows); err != nil {
+				setErr(fmt.Errorf("sql2csv: writing shard %d: %w", i, err))
+			}
+		}()
+	}
+
+	wg.Wait()
+	return firstErr
+}
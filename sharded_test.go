@@ -0,0 +1,177 @@
+package sql2csv
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// shardDelayDriver is a minimal database/sql driver standing in for a real
+// one, used to exercise ConverterPool.Write's concurrency and ordered-merge
+// behavior deterministically: each query returns a single row naming the
+// shard it was given, after sleeping longer for lower-numbered shards, so a
+// correct implementation has to reorder completions back into shard order
+// rather than just reflecting completion order.
+type shardDelayDriver struct{}
+
+func (shardDelayDriver) Open(string) (driver.Conn, error) { return &shardDelayConn{}, nil }
+
+type shardDelayConn struct{}
+
+func (c *shardDelayConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("shardDelayConn: use QueryContext")
+}
+func (c *shardDelayConn) Close() error               { return nil }
+func (c *shardDelayConn) Begin() (driver.Tx, error)  { return nil, fmt.Errorf("not supported") }
+func (c *shardDelayConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expected a shard index argument")
+	}
+	shard := fmt.Sprintf("%v", args[0].Value)
+	// Sleep longer for earlier shards so, without correct ordering, a
+	// later (faster) shard would otherwise land in the output first.
+	if n, ok := args[0].Value.(int64); ok {
+		time.Sleep(time.Duration(4-n) * 15 * time.Millisecond)
+	}
+	return &shardDelayRows{shard: shard}, nil
+}
+
+type shardDelayRows struct {
+	shard string
+	done  bool
+}
+
+func (r *shardDelayRows) Columns() []string { return []string{"shard"} }
+func (r *shardDelayRows) Close() error      { return nil }
+func (r *shardDelayRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.shard
+	r.done = true
+	return nil
+}
+
+// manyRowsDriver is a second stand-in driver, used to check that
+// ConverterPool.Write merges shards that each return many rows (5000 here,
+// about 10 KB of output per shard, within shardWriteBufferSize) whole and
+// in shard order, with every shard converting concurrently into its own
+// buffer.
+type manyRowsDriver struct{}
+
+func (manyRowsDriver) Open(string) (driver.Conn, error) { return &manyRowsConn{}, nil }
+
+type manyRowsConn struct{}
+
+func (c *manyRowsConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("manyRowsConn: use QueryContext")
+}
+func (c *manyRowsConn) Close() error              { return nil }
+func (c *manyRowsConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("not supported") }
+func (c *manyRowsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expected a shard index argument")
+	}
+	return &manyRowsRows{shard: fmt.Sprintf("%v", args[0].Value), remaining: 5000}, nil
+}
+
+type manyRowsRows struct {
+	shard     string
+	remaining int
+}
+
+func (r *manyRowsRows) Columns() []string { return []string{"shard"} }
+func (r *manyRowsRows) Close() error      { return nil }
+func (r *manyRowsRows) Next(dest []driver.Value) error {
+	if r.remaining == 0 {
+		return io.EOF
+	}
+	dest[0] = r.shard
+	r.remaining--
+	return nil
+}
+
+var registerManyRowsDriver = sync.OnceFunc(func() {
+	sql.Register("sql2csv-many-rows-test", manyRowsDriver{})
+})
+
+func TestConverterPoolWriteManyRowsPerShard(t *testing.T) {
+	registerManyRowsDriver()
+	db, err := sql.Open("sql2csv-many-rows-test", "")
+	if err != nil {
+		t.Fatalf("error opening test db: %v", err)
+	}
+
+	pool := &ConverterPool{
+		DB:            db,
+		QueryTemplate: "SELECT shard WHERE n = ?",
+		Shards: []ShardRange{
+			{Low: int64(0)},
+			{Low: int64(1)},
+			{Low: int64(2)},
+		},
+		Concurrency: 3,
+	}
+
+	buf := &bytes.Buffer{}
+	if err = pool.Write(context.Background(), buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if lines[0] != "shard" {
+		t.Fatalf("expected a single header row, got: %q", lines[0])
+	}
+	lines = lines[1:]
+	if len(lines) != 3*5000 {
+		t.Fatalf("expected %d data rows, got %d", 3*5000, len(lines))
+	}
+	for i, line := range lines {
+		want := fmt.Sprintf("%d", i/5000)
+		if line != want {
+			t.Fatalf("row %d: expected shard %q, got %q (shard order not preserved)", i, want, line)
+		}
+	}
+}
+
+var registerShardDelayDriver = sync.OnceFunc(func() {
+	sql.Register("sql2csv-shard-delay-test", shardDelayDriver{})
+})
+
+func TestConverterPoolWritePreservesShardOrder(t *testing.T) {
+	registerShardDelayDriver()
+	db, err := sql.Open("sql2csv-shard-delay-test", "")
+	if err != nil {
+		t.Fatalf("error opening test db: %v", err)
+	}
+
+	pool := &ConverterPool{
+		DB:            db,
+		QueryTemplate: "SELECT shard WHERE n = ?",
+		Shards: []ShardRange{
+			{Low: int64(0)},
+			{Low: int64(1)},
+			{Low: int64(2)},
+		},
+		Concurrency: 3,
+	}
+
+	buf := &bytes.Buffer{}
+	if err = pool.Write(context.Background(), buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	expected := "shard\n0\n1\n2\n"
+	assertCsvMatch(t, expected, buf.String())
+
+	if n := strings.Count(buf.String(), "shard\n"); n != 1 {
+		t.Errorf("expected the header to appear exactly once, got %d times in:\n%s", n, buf.String())
+	}
+}
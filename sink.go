@@ -0,0 +1,154 @@
+package sql2csv
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Sink is an opened, write-only destination for a conversion's output. Close
+// must be called even after a write error, so an interrupted conversion
+// still finalizes (or aborts) the underlying file/upload correctly.
+type Sink interface {
+	io.Writer
+	io.Closer
+}
+
+// SinkOpener opens a Sink for a URL of the scheme it's registered under.
+type SinkOpener func(ctx context.Context, u *url.URL) (Sink, error)
+
+// sinkOpeners holds one SinkOpener per URL scheme. file:// is registered
+// here; gs://, s3:// and az:// are intentionally not, since backing them
+// means pulling in their cloud SDKs, which this package doesn't depend on.
+var sinkOpeners = map[string]SinkOpener{
+	"file": openFileSink,
+}
+
+// unimplementedCloudSchemes names the object-store schemes this package was
+// asked to support but doesn't ship a SinkOpener for, so WriteToURL can tell
+// a caller who reaches for gs://, s3:// or az:// that the scheme is known
+// and unimplemented, not simply unrecognized.
+var unimplementedCloudSchemes = map[string]string{
+	"gs": "Google Cloud Storage",
+	"s3": "Amazon S3",
+	"az": "Azure Blob Storage",
+}
+
+// RegisterSinkOpener registers opener as the handler for rawURL schemes
+// matching scheme (without the "://"), so WriteToURL can dispatch to it.
+// Wire up gs://, s3:// or az:// support in your own program against
+// whichever object-store SDK you already depend on, e.g.:
+//
+//	sql2csv.RegisterSinkOpener("gs", gcssink.Open)
+func RegisterSinkOpener(scheme string, opener SinkOpener) {
+	sinkOpeners[scheme] = opener
+}
+
+func openFileSink(_ context.Context, u *url.URL) (Sink, error) {
+	path := u.Path
+	if path == "" {
+		path = u.Opaque
+	}
+	return os.Create(path)
+}
+
+// Compression picks whether WriteToURL gzip-compresses its output.
+type Compression int
+
+const (
+	// CompressionAuto gzips iff the URL's path ends in ".gz". This is the default.
+	CompressionAuto Compression = iota
+	CompressionNone
+	CompressionGzip
+)
+
+// Option configures WriteToURL.
+type Option func(*writeToURLOptions)
+
+type writeToURLOptions struct {
+	compression  Compression
+	newConverter func(rows *sql.Rows) *Converter
+}
+
+// WithCompression overrides WriteToURL's default of inferring gzip from a
+// ".gz" suffix on the destination URL.
+func WithCompression(c Compression) Option {
+	return func(o *writeToURLOptions) { o.compression = c }
+}
+
+// WithConverter lets you configure the Converter (Encoder, Delimiter,
+// TimeFormat, ...) WriteToURL uses, instead of sql2csv.NewConverter's defaults.
+func WithConverter(newConverter func(rows *sql.Rows) *Converter) Option {
+	return func(o *writeToURLOptions) { o.newConverter = newConverter }
+}
+
+// WriteToURL converts rows and writes them to rawURL, picking a Sink by the
+// URL's scheme and transparently gzip-compressing when the path ends in
+// ".gz" or compression is forced with WithCompression.
+//
+// Only file:// is built in. gs://, s3:// and az:// (and any other scheme)
+// return an error unless you first register a SinkOpener for them with
+// RegisterSinkOpener against whichever object-store SDK your program
+// already depends on — this package doesn't vendor cloud SDKs, so it
+// doesn't eliminate that wiring, only gives it a single pluggable seam.
+func WriteToURL(ctx context.Context, rawURL string, rows *sql.Rows, opts ...Option) error {
+	options := writeToURLOptions{compression: CompressionAuto}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("sql2csv: parsing %q: %w", rawURL, err)
+	}
+
+	scheme := u.Scheme
+	if scheme == "" {
+		scheme = "file"
+	}
+	opener, ok := sinkOpeners[scheme]
+	if !ok {
+		if name, known := unimplementedCloudSchemes[scheme]; known {
+			return fmt.Errorf("sql2csv: %s (%s://) isn't implemented by this package; register a SinkOpener for it with RegisterSinkOpener against your own %s SDK", name, scheme, name)
+		}
+		return fmt.Errorf("sql2csv: no Sink registered for scheme %q (see RegisterSinkOpener)", scheme)
+	}
+
+	sink, err := opener(ctx, u)
+	if err != nil {
+		return fmt.Errorf("sql2csv: opening %q: %w", rawURL, err)
+	}
+
+	useGzip := options.compression == CompressionGzip ||
+		(options.compression == CompressionAuto && strings.HasSuffix(u.Path, ".gz"))
+
+	var w io.Writer = sink
+	var gz *gzip.Writer
+	if useGzip {
+		gz = gzip.NewWriter(sink)
+		w = gz
+	}
+
+	converter := NewConverter(rows)
+	if options.newConverter != nil {
+		converter = options.newConverter(rows)
+	}
+
+	writeErr := converter.Write(w)
+
+	if gz != nil {
+		if err = gz.Close(); writeErr == nil {
+			writeErr = err
+		}
+	}
+	if err = sink.Close(); writeErr == nil {
+		writeErr = err
+	}
+
+	return writeErr
+}
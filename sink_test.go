@@ -0,0 +1,68 @@
+package sql2csv
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteToURLFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+
+	if err := WriteToURL(context.Background(), "file://"+path, getTestRows(t)); err != nil {
+		t.Fatalf("error in WriteToURL: %v", err)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading %v: %v", path, err)
+	}
+
+	assertCsvMatch(t, "name,age,bdate\nAlice,1,1973-11-29 21:33:09 +0000 UTC\n", string(content))
+}
+
+func TestWriteToURLGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv.gz")
+
+	if err := WriteToURL(context.Background(), "file://"+path, getTestRows(t)); err != nil {
+		t.Fatalf("error in WriteToURL: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("error opening %v: %v", path, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("error opening gzip reader: %v", err)
+	}
+	content, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("error reading gzip content: %v", err)
+	}
+
+	assertCsvMatch(t, "name,age,bdate\nAlice,1,1973-11-29 21:33:09 +0000 UTC\n", string(content))
+}
+
+func TestRegisterSinkOpenerUnknownScheme(t *testing.T) {
+	err := WriteToURL(context.Background(), "xyz://bucket/key.csv", getTestRows(t))
+	if err == nil {
+		t.Fatal("expected an error for an unregistered scheme, got nil")
+	}
+}
+
+func TestWriteToURLUnimplementedCloudScheme(t *testing.T) {
+	err := WriteToURL(context.Background(), "gs://bucket/key.csv", getTestRows(t))
+	if err == nil {
+		t.Fatal("expected an error for gs://, which this package doesn't implement a SinkOpener for")
+	}
+	if !strings.Contains(err.Error(), "Google Cloud Storage") || !strings.Contains(err.Error(), "RegisterSinkOpener") {
+		t.Errorf("expected the error to name the scheme as known-but-unimplemented and point at RegisterSinkOpener, got: %v", err)
+	}
+}
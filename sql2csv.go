@@ -7,7 +7,6 @@ package sql2csv
 import (
 	"bytes"
 	"database/sql"
-	"encoding/csv"
 	"fmt"
 	"github.com/google/uuid"
 	"io"
@@ -49,7 +48,9 @@ type Converter struct {
 	Headers      []string // Column headers to use (default is rows.Columns())
 	WriteHeaders bool     // Flag to output headers in your CSV (default is true)
 	TimeFormat   string   // Format string for any time.Time values (default is time's default)
-	Delimiter    rune     // Delimiter to use in your CSV (default is comma)
+	Delimiter    rune     // Delimiter to use in your CSV (default is comma), ignored if Encoder is set
+	Encoder      Encoder  // Row-serialization format to use (default is CSVEncoder)
+	OnError      ErrorHandler // Called on a row/column conversion error (default is Abort)
 
 	rows             *sql.Rows
 	rowPostProcessor CsvRowPostProcessorFunc
@@ -60,6 +61,15 @@ func (c *Converter) SetRowPostProcessor(processor CsvRowPostProcessorFunc) {
 	c.rowPostProcessor = processor
 }
 
+// WithEncoder lets you swap out the row-serialization format, e.g.
+// converter.WithEncoder(&sql2csv.JSONLinesEncoder{}) to get JSON Lines
+// output instead of CSV. It returns the Converter so it can be chained
+// off NewConverter.
+func (c *Converter) WithEncoder(encoder Encoder) *Converter {
+	c.Encoder = encoder
+	return c
+}
+
 // String returns the CSV as a string in an fmt package friendly way
 func (c Converter) String() string {
 	s, err := c.WriteString()
@@ -92,12 +102,17 @@ func (c Converter) WriteFile(csvFileName string) error {
 	return f.Close()
 }
 
-// Write writes the CSV to the Writer provided
+// Write writes the rows to the Writer provided, using c.Encoder to
+// serialize them (CSV, the original behaviour, if c.Encoder isn't set).
 func (c Converter) Write(writer io.Writer) error {
 	rows := c.rows
-	csvWriter := csv.NewWriter(writer)
-	if c.Delimiter != '\x00' {
-		csvWriter.Comma = c.Delimiter
+
+	encoder := c.Encoder
+	if encoder == nil {
+		encoder = &CSVEncoder{Comma: c.Delimiter}
+	}
+	if err := encoder.Open(writer); err != nil {
+		return err
 	}
 
 	columns, err := rows.ColumnTypes()
@@ -118,7 +133,7 @@ func (c Converter) Write(writer io.Writer) error {
 			}
 			headers = columnNames
 		}
-		err = csvWriter.Write(headers)
+		err = encoder.WriteHeader(headers)
 		if err != nil {
 			// TODO wrap err to say it was an issue with headers?
 			return err
@@ -129,15 +144,22 @@ func (c Converter) Write(writer io.Writer) error {
 	values := make([]interface{}, count)
 	valPointers := make([]interface{}, count)
 
+	rowIndex := -1
 	for rows.Next() {
+		rowIndex++
 		row := make([]string, count)
+		skipRow := false
 
 		for i := range columns {
 			valPointers[i] = &values[i]
 		}
 
 		if err = rows.Scan(valPointers...); err != nil {
-			return err
+			convErr := &ConversionError{RowIndex: rowIndex, Err: err}
+			if action := c.handleConversionError(convErr); action.Kind == AbortConversion {
+				return convErr
+			}
+			continue
 		}
 
 		for i, column := range columns {
@@ -146,11 +168,30 @@ func (c Converter) Write(writer io.Writer) error {
 				case "UNIQUEIDENTIFIER":
 					var v uuid.UUID
 					if v, err = uuid.FromBytes(b); err != nil {
-						return err
+						convErr := &ConversionError{
+							RowIndex:   rowIndex,
+							ColumnName: column.Name(),
+							ColumnType: column,
+							RawValue:   b,
+							Err:        err,
+						}
+						switch action := c.handleConversionError(convErr); action.Kind {
+						case SkipRow:
+							skipRow = true
+						case UseFallback:
+							values[i] = action.Fallback
+							row[i] = action.Fallback
+						default:
+							return convErr
+						}
+					} else {
+						values[i] = v
+						row[i] = v.String()
 					}
-					row[i] = v.String()
 				default:
-					row[i] = string(b)
+					s := string(b)
+					values[i] = s
+					row[i] = s
 				}
 			} else {
 				var value interface{}
@@ -166,6 +207,12 @@ func (c Converter) Write(writer io.Writer) error {
 					row[i] = fmt.Sprintf("%v", value)
 				}
 			}
+			if skipRow {
+				break
+			}
+		}
+		if skipRow {
+			continue
 		}
 
 		writeRow := true
@@ -173,16 +220,19 @@ func (c Converter) Write(writer io.Writer) error {
 			writeRow, row = c.rowPostProcessor(row, columns)
 		}
 		if writeRow {
-			err = csvWriter.Write(row)
-			if err != nil {
-				// TODO wrap this err to give context as to why it failed?
-				return err
+			if err = encoder.WriteRow(row, values, columns); err != nil {
+				convErr := &ConversionError{RowIndex: rowIndex, Err: err}
+				if action := c.handleConversionError(convErr); action.Kind == AbortConversion {
+					return convErr
+				}
 			}
 		}
 	}
 	err = rows.Err()
 
-	csvWriter.Flush()
+	if flushErr := encoder.Flush(); err == nil {
+		err = flushErr
+	}
 
 	return err
 }
@@ -0,0 +1,96 @@
+package sql2csv
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SQLInsertEncoder writes rows as `INSERT INTO table (...) VALUES (...)`
+// statements instead of a delimited format, the way mysqldump/dumpling can.
+// Rows are batched into multi-row INSERT statements up to BatchSize rows
+// each (BatchSize <= 0 means one statement per row).
+type SQLInsertEncoder struct {
+	TableName       string // table name to insert into, required
+	BatchSize       int    // rows per INSERT statement; <= 0 means 1
+	QuoteIdentifier bool   // wrap TableName/column names in backticks
+
+	w       *bufio.Writer
+	headers []string
+	pending []string // pre-rendered "(v1, v2, ...)" tuples awaiting flush
+}
+
+// Open implements Encoder.
+func (e *SQLInsertEncoder) Open(w io.Writer) error {
+	e.w = bufio.NewWriter(w)
+	e.pending = nil
+	return nil
+}
+
+// WriteHeader implements Encoder. The headers become the column list shared
+// by every INSERT statement written for this conversion.
+func (e *SQLInsertEncoder) WriteHeader(headers []string) error {
+	e.headers = headers
+	return nil
+}
+
+// WriteRow implements Encoder.
+func (e *SQLInsertEncoder) WriteRow(row []string, rawValues []interface{}, _ []*sql.ColumnType) error {
+	values := make([]string, len(row))
+	for i, s := range row {
+		if i < len(rawValues) && rawValues[i] == nil {
+			values[i] = "NULL"
+			continue
+		}
+		values[i] = quoteSQLString(s)
+	}
+	e.pending = append(e.pending, "("+strings.Join(values, ", ")+")")
+
+	batchSize := e.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	if len(e.pending) >= batchSize {
+		return e.flushBatch()
+	}
+	return nil
+}
+
+// Flush implements Encoder, writing out any rows still pending a full batch.
+func (e *SQLInsertEncoder) Flush() error {
+	if err := e.flushBatch(); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *SQLInsertEncoder) flushBatch() error {
+	if len(e.pending) == 0 {
+		return nil
+	}
+
+	table := e.TableName
+	columns := make([]string, len(e.headers))
+	copy(columns, e.headers)
+	if e.QuoteIdentifier {
+		table = quoteSQLIdentifier(table)
+		for i, c := range columns {
+			columns[i] = quoteSQLIdentifier(c)
+		}
+	}
+
+	_, err := fmt.Fprintf(e.w, "INSERT INTO %s (%s) VALUES %s;\n",
+		table, strings.Join(columns, ", "), strings.Join(e.pending, ", "))
+	e.pending = e.pending[:0]
+	return err
+}
+
+func quoteSQLIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func quoteSQLString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,162 @@
+package sql2csv
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// MarshalCSV is implemented by struct field types that know how to render
+// themselves as a single CSV cell, e.g. a money, geometry or JSON-in-column
+// type. WriteTyped calls it instead of falling back to
+// fmt.Sprintf("%v", value).
+type MarshalCSV interface {
+	MarshalCSV() (string, error)
+}
+
+// WriteTyped writes rows to writer as CSV, using the `csv:"name,omitempty"`
+// struct tags (and field order) of sample's element type to determine the
+// output columns, instead of defaulting to rows.Columns(). sample must be a
+// pointer to a slice of structs (or struct pointers), e.g. &[]Person{}; it is
+// only consulted for its element type and is never populated or retained —
+// unlike json.Unmarshal's dest, WriteTyped streams rows straight to writer
+// instead of building a slice in memory.
+//
+// A field tagged `csv:"-"` is skipped. A field with no csv tag uses its Go
+// field name as the header. Fields whose type implements MarshalCSV are
+// rendered with it; everything else falls back to fmt.Sprintf("%v", ...),
+// same as Converter.Write.
+func WriteTyped(writer io.Writer, rows *sql.Rows, sample interface{}) error {
+	fields, elemType, err := csvStructFields(sample)
+	if err != nil {
+		return err
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	byColumn := make(map[string]int, len(fields))
+	for i, f := range fields {
+		byColumn[strings.ToLower(f.name)] = i
+	}
+
+	encoder := &CSVEncoder{}
+	if err = encoder.Open(writer); err != nil {
+		return err
+	}
+
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.name
+	}
+	if err = encoder.WriteHeader(headers); err != nil {
+		return err
+	}
+
+	scanTargets := make([]interface{}, len(columns))
+	for rows.Next() {
+		instance := reflect.New(elemType).Elem()
+
+		for i, column := range columns {
+			if fi, ok := byColumn[strings.ToLower(column)]; ok {
+				scanTargets[i] = instance.Field(fields[fi].index).Addr().Interface()
+			} else {
+				var discard interface{}
+				scanTargets[i] = &discard
+			}
+		}
+
+		if err = rows.Scan(scanTargets...); err != nil {
+			return err
+		}
+
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			if row[i], err = marshalField(instance.Field(f.index), f); err != nil {
+				return err
+			}
+		}
+
+		if err = encoder.WriteRow(row, nil, nil); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	return encoder.Flush()
+}
+
+// csvField describes one destination struct field: the CSV header it maps
+// to, its index within the struct, and whether a zero value should be
+// rendered as an empty cell rather than its fmt.Sprintf default.
+type csvField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+func csvStructFields(sample interface{}) ([]csvField, reflect.Type, error) {
+	v := reflect.ValueOf(sample)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("sql2csv: WriteTyped requires sample to be a pointer to a slice, got %T", sample)
+	}
+
+	elemType := v.Elem().Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("sql2csv: WriteTyped requires a slice of structs, got %v", elemType)
+	}
+
+	fields := make([]csvField, 0, elemType.NumField())
+	for i := 0; i < elemType.NumField(); i++ {
+		sf := elemType.Field(i)
+		if sf.PkgPath != "" { // unexported field, can't Scan into it
+			continue
+		}
+
+		tag := sf.Tag.Get("csv")
+		if tag == "-" {
+			continue
+		}
+
+		name, omitEmpty := sf.Name, false
+		if tag != "" {
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			omitEmpty = len(parts) > 1 && parts[1] == "omitempty"
+		}
+
+		fields = append(fields, csvField{name: name, index: i, omitEmpty: omitEmpty})
+	}
+	return fields, elemType, nil
+}
+
+// marshalField renders a single destination field as a CSV cell, preferring
+// a MarshalCSV implementation on the field's type over the generic
+// fmt.Sprintf("%v", ...) fallback.
+func marshalField(value reflect.Value, f csvField) (string, error) {
+	if value.CanAddr() {
+		if m, ok := value.Addr().Interface().(MarshalCSV); ok {
+			return m.MarshalCSV()
+		}
+	}
+	if m, ok := value.Interface().(MarshalCSV); ok {
+		return m.MarshalCSV()
+	}
+
+	if f.omitEmpty && value.IsZero() {
+		return "", nil
+	}
+
+	return fmt.Sprintf("%v", value.Interface()), nil
+}
@@ -0,0 +1,51 @@
+package sql2csv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// upperName demonstrates a field type that renders itself via MarshalCSV
+// rather than through the fmt.Sprintf("%v", ...) fallback.
+type upperName string
+
+func (u upperName) MarshalCSV() (string, error) {
+	return strings.ToUpper(string(u)), nil
+}
+
+type typedPerson struct {
+	Bdate time.Time `csv:"bdate"`
+	Name  upperName `csv:"name"`
+	Age   int32     `csv:"age"`
+}
+
+func TestWriteTyped(t *testing.T) {
+	rows := getTestRows(t)
+	buffer := &bytes.Buffer{}
+
+	if err := WriteTyped(buffer, rows, &[]typedPerson{}); err != nil {
+		t.Fatalf("error in WriteTyped: %v", err)
+	}
+
+	expected := "bdate,name,age\n1973-11-29 21:33:09 +0000 UTC,ALICE,1\n"
+	assertCsvMatch(t, expected, buffer.String())
+}
+
+func TestWriteTypedSkipsDashTag(t *testing.T) {
+	type partial struct {
+		Name   string `csv:"name"`
+		Ignore string `csv:"-"`
+	}
+
+	rows := getTestRows(t)
+	buffer := &bytes.Buffer{}
+
+	if err := WriteTyped(buffer, rows, &[]partial{}); err != nil {
+		t.Fatalf("error in WriteTyped: %v", err)
+	}
+
+	expected := "name\nAlice\n"
+	assertCsvMatch(t, expected, buffer.String())
+}